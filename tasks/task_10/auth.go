@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+const (
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 7 * 24 * time.Hour
+)
+
+type AuthConfig struct {
+	Secret      []byte
+	AccessTTL   time.Duration
+	RefreshTTL  time.Duration
+	LoginSecret []byte // shared secret callers must present to Login
+}
+
+// LoadAuthConfig reads JWT_SECRET and LOGIN_SECRET (both required) and the
+// optional JWT_ACCESS_TTL / JWT_REFRESH_TTL Go duration strings (e.g. "15m")
+// from the environment. LOGIN_SECRET is the shared secret Login checks
+// before issuing a token for a userID - without it, anyone who can reach
+// Login could mint a token for any userID they like.
+func LoadAuthConfig() (AuthConfig, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return AuthConfig{}, fmt.Errorf("JWT_SECRET is required")
+	}
+
+	loginSecret := os.Getenv("LOGIN_SECRET")
+	if loginSecret == "" {
+		return AuthConfig{}, fmt.Errorf("LOGIN_SECRET is required")
+	}
+
+	accessTTL := defaultAccessTTL
+	if v := os.Getenv("JWT_ACCESS_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return AuthConfig{}, fmt.Errorf("invalid JWT_ACCESS_TTL: %w", err)
+		}
+		accessTTL = d
+	}
+
+	refreshTTL := defaultRefreshTTL
+	if v := os.Getenv("JWT_REFRESH_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return AuthConfig{}, fmt.Errorf("invalid JWT_REFRESH_TTL: %w", err)
+		}
+		refreshTTL = d
+	}
+
+	return AuthConfig{
+		Secret:      []byte(secret),
+		AccessTTL:   accessTTL,
+		RefreshTTL:  refreshTTL,
+		LoginSecret: []byte(loginSecret),
+	}, nil
+}
+
+type tokenKind string
+
+const (
+	accessToken  tokenKind = "access"
+	refreshToken tokenKind = "refresh"
+)
+
+type jwtClaims struct {
+	UserID string    `json:"sub"`
+	Kind   tokenKind `json:"kind"`
+	Exp    int64     `json:"exp"`
+}
+
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// sign produces a compact HS256 JWT for c. There's no external JWT
+// dependency here, just base64url(header).base64url(claims).hmac, which is
+// all HS256 is.
+func (a AuthConfig) sign(c jwtClaims) (string, error) {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(body)
+
+	mac := hmac.New(sha256.New, a.Secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verify checks the signature and expiry of token and returns its claims.
+func (a AuthConfig) verify(token string) (jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return jwtClaims{}, ErrInvalidToken
+	}
+
+	mac := hmac.New(sha256.New, a.Secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	wantSig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(wantSig, mac.Sum(nil)) {
+		return jwtClaims{}, ErrInvalidToken
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return jwtClaims{}, ErrInvalidToken
+	}
+	var c jwtClaims
+	if err := json.Unmarshal(body, &c); err != nil {
+		return jwtClaims{}, ErrInvalidToken
+	}
+	if time.Now().Unix() >= c.Exp {
+		return jwtClaims{}, ErrInvalidToken
+	}
+	return c, nil
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// issue mints a fresh access/refresh token pair for userID.
+func (a AuthConfig) issue(userID string) (TokenPair, error) {
+	now := time.Now()
+	access, err := a.sign(jwtClaims{UserID: userID, Kind: accessToken, Exp: now.Add(a.AccessTTL).Unix()})
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := a.sign(jwtClaims{UserID: userID, Kind: refreshToken, Exp: now.Add(a.RefreshTTL).Unix()})
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{AccessToken: access, RefreshToken: refresh}, nil
+}
+
+type contextKey int
+
+const userIDContextKey contextKey = iota
+
+// RequireAuth validates the Authorization: Bearer <access token> header and
+// injects the token's userID into the request context, rejecting the
+// request with 401 if the token is missing, malformed, expired, or not an
+// access token.
+func (a AuthConfig) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "Missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := a.verify(token)
+		if err != nil || claims.Kind != accessToken {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDContextKey, claims.UserID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// UserIDFromContext returns the userID injected by RequireAuth, if any.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(string)
+	return userID, ok
+}
+
+type AuthHandler struct {
+	auth AuthConfig
+}
+
+type LoginRequest struct {
+	UserID string `json:"userID"`
+	Secret string `json:"secret"`
+}
+
+// Login issues a token pair for the given userID, gated on req.Secret
+// matching the server's configured LoginSecret. This is still not real
+// per-user authentication - it's a single shared secret, not a credential
+// store - but unlike issuing tokens unconditionally, a caller without the
+// secret can no longer mint a token for an arbitrary userID.
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	userID := strings.TrimSpace(req.UserID)
+	if userID == "" {
+		http.Error(w, "Empty userID", http.StatusBadRequest)
+		return
+	}
+
+	if len(h.auth.LoginSecret) == 0 || !hmac.Equal([]byte(req.Secret), h.auth.LoginSecret) {
+		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	pair, err := h.auth.issue(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pair)
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Refresh exchanges a valid refresh token for a new token pair.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := h.auth.verify(req.RefreshToken)
+	if err != nil || claims.Kind != refreshToken {
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	pair, err := h.auth.issue(claims.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(pair)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAuthConfig() AuthConfig {
+	return AuthConfig{
+		Secret:      []byte("test-secret"),
+		AccessTTL:   time.Minute,
+		RefreshTTL:  time.Hour,
+		LoginSecret: []byte("test-login-secret"),
+	}
+}
+
+func TestAuthConfigSignVerifyRoundTrip(t *testing.T) {
+	auth := testAuthConfig()
+
+	pair, err := auth.issue("alice")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	claims, err := auth.verify(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("verify(access): %v", err)
+	}
+	if claims.UserID != "alice" || claims.Kind != accessToken {
+		t.Fatalf("unexpected access claims: %+v", claims)
+	}
+
+	claims, err = auth.verify(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("verify(refresh): %v", err)
+	}
+	if claims.UserID != "alice" || claims.Kind != refreshToken {
+		t.Fatalf("unexpected refresh claims: %+v", claims)
+	}
+}
+
+func TestAuthConfigVerifyRejectsTamperedAndExpiredTokens(t *testing.T) {
+	auth := testAuthConfig()
+
+	pair, err := auth.issue("alice")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if _, err := auth.verify(pair.AccessToken + "x"); err == nil {
+		t.Fatalf("verify accepted a tampered token")
+	}
+
+	expired := AuthConfig{Secret: auth.Secret, AccessTTL: -time.Minute, RefreshTTL: time.Hour}
+	pair, err = expired.issue("alice")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	if _, err := auth.verify(pair.AccessToken); err == nil {
+		t.Fatalf("verify accepted an expired token")
+	}
+}
+
+func TestRequireAuthInjectsUserID(t *testing.T) {
+	auth := testAuthConfig()
+	pair, err := auth.issue("alice")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	var gotUserID string
+	var gotOK bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID, gotOK = UserIDFromContext(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	auth.RequireAuth(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	if !gotOK || gotUserID != "alice" {
+		t.Fatalf("UserIDFromContext = %q, %v; want alice, true", gotUserID, gotOK)
+	}
+}
+
+func TestRequireAuthRejectsMissingAndWrongKindTokens(t *testing.T) {
+	auth := testAuthConfig()
+	pair, err := auth.issue("alice")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("next handler should not run")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	rec := httptest.NewRecorder()
+	auth.RequireAuth(next).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("missing token: status = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.RefreshToken)
+	rec = httptest.NewRecorder()
+	auth.RequireAuth(next).ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh token as bearer: status = %d, want 401", rec.Code)
+	}
+}
+
+func postJSON(t *testing.T, h http.HandlerFunc, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+	rec := httptest.NewRecorder()
+	h(rec, req)
+	return rec
+}
+
+func TestAuthHandlerLoginRequiresCorrectSecret(t *testing.T) {
+	h := &AuthHandler{auth: testAuthConfig()}
+
+	rec := postJSON(t, h.Login, "/auth/login", LoginRequest{UserID: "alice", Secret: "wrong"})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong secret: status = %d, want 401", rec.Code)
+	}
+
+	rec = postJSON(t, h.Login, "/auth/login", LoginRequest{UserID: "alice", Secret: "test-login-secret"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("correct secret: status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+
+	var pair TokenPair
+	if err := json.Unmarshal(rec.Body.Bytes(), &pair); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	claims, err := h.auth.verify(pair.AccessToken)
+	if err != nil || claims.UserID != "alice" || claims.Kind != accessToken {
+		t.Fatalf("unexpected issued access token claims: %+v, err=%v", claims, err)
+	}
+}
+
+func TestAuthHandlerLoginRejectsEmptyUserID(t *testing.T) {
+	h := &AuthHandler{auth: testAuthConfig()}
+
+	rec := postJSON(t, h.Login, "/auth/login", LoginRequest{UserID: "  ", Secret: "test-login-secret"})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty userID: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestAuthHandlerRefresh(t *testing.T) {
+	h := &AuthHandler{auth: testAuthConfig()}
+	pair, err := h.auth.issue("alice")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+
+	rec := postJSON(t, h.Refresh, "/auth/refresh", RefreshRequest{RefreshToken: pair.RefreshToken})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+	var fresh TokenPair
+	if err := json.Unmarshal(rec.Body.Bytes(), &fresh); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	claims, err := h.auth.verify(fresh.AccessToken)
+	if err != nil || claims.UserID != "alice" {
+		t.Fatalf("unexpected refreshed claims: %+v, err=%v", claims, err)
+	}
+
+	rec = postJSON(t, h.Refresh, "/auth/refresh", RefreshRequest{RefreshToken: pair.AccessToken})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh with an access token: status = %d, want 401", rec.Code)
+	}
+
+	rec = postJSON(t, h.Refresh, "/auth/refresh", RefreshRequest{RefreshToken: "garbage"})
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("refresh with a malformed token: status = %d, want 401", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Invalid refresh token") {
+		t.Fatalf("body = %q, want an invalid-refresh-token error", rec.Body.String())
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	subscriberBuffer     = 16
+	sseHeartbeatInterval = 15 * time.Second
+)
+
+// TaskEvent describes a single change a Notifier fans out to subscribers.
+// Event is one of "created", "updated" or "deleted".
+type TaskEvent struct {
+	Event string
+	Task  Task
+}
+
+// Notifier is an optional TaskRepo capability: backends that support it let
+// callers subscribe to live task changes for GET /tasks/events. InMemory
+// implements it; SQL-backed repos currently don't.
+type Notifier interface {
+	// Subscribe registers a new subscriber for owner and returns a channel
+	// of live events, the repo's revision at the moment of subscribing,
+	// and a cancel func the caller must run when done. lastEventID is
+	// accepted for symmetry with ChangesSince callers but Subscribe itself
+	// only ever delivers events from this point forward - callers replay
+	// the backlog via ChangesSince, bounded by the returned revision so
+	// nothing is replayed twice (see HTTPHandler.Events).
+	Subscribe(owner string, lastEventID uint64) (events <-chan TaskEvent, asOf uint64, cancel func())
+}
+
+// Subscribe registers ch to receive every future TaskEvent for owner, and
+// reports the repo's revision as of the moment ch is registered. Reading
+// m.rev under the same m.mu hold that guards registration matters: any
+// write already in flight finishes (and has its chance to notify, which
+// does nothing since ch isn't registered yet) before Subscribe reads rev,
+// and any write after that is guaranteed to reach ch live. That makes
+// "replay everything with Rev <= asOf, then rely on ch for the rest" exact,
+// with no gap and no overlap - see HTTPHandler.Events.
+//
+// If a subscriber's buffer fills up (a slow consumer), it is dropped and
+// its channel closed rather than blocking writers; the client is expected
+// to reconnect with Last-Event-ID to catch up via ChangesSince.
+func (m *InMemory) Subscribe(owner string, lastEventID uint64) (<-chan TaskEvent, uint64, func()) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ch := make(chan TaskEvent, subscriberBuffer)
+
+	m.subMu.Lock()
+	if m.subs == nil {
+		m.subs = make(map[string]map[chan TaskEvent]struct{})
+	}
+	if m.subs[owner] == nil {
+		m.subs[owner] = make(map[chan TaskEvent]struct{})
+	}
+	m.subs[owner][ch] = struct{}{}
+	m.subMu.Unlock()
+
+	cancel := func() {
+		m.subMu.Lock()
+		delete(m.subs[owner], ch)
+		m.subMu.Unlock()
+	}
+	return ch, m.rev, cancel
+}
+
+func (m *InMemory) notify(owner string, evt TaskEvent) {
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+	for ch := range m.subs[owner] {
+		select {
+		case ch <- evt:
+		default:
+			delete(m.subs[owner], ch)
+			close(ch)
+		}
+	}
+}
+
+// Events handles GET /tasks/events: an SSE stream of the caller's own task
+// changes. It replays everything since Last-Event-ID (paired with Task.Rev)
+// via TaskRepo.ChangesSince, then switches to live events from a Notifier
+// subscription, sending a heartbeat comment every 15s to keep proxies from
+// closing the idle connection.
+func (h *HTTPHandler) Events(w http.ResponseWriter, r *http.Request) {
+	if h.notifier == nil {
+		http.Error(w, "Event stream not supported by this storage backend", http.StatusNotImplemented)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	owner, _ := UserIDFromContext(r.Context())
+
+	var lastEventID uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		if parsed, err := strconv.ParseUint(v, 10, 64); err == nil {
+			lastEventID = parsed
+		}
+	}
+
+	events, asOf, cancel := h.notifier.Subscribe(owner, lastEventID)
+	defer cancel()
+
+	backlog, _, err := h.repo.ChangesSince(r.Context(), owner, lastEventID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, task := range backlog {
+		if task.Rev > asOf {
+			// Happened after Subscribe registered; events will deliver it
+			// live, so replaying it here too would duplicate it.
+			continue
+		}
+		writeSSEEvent(w, eventNameFor(task), task)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return // dropped for being too slow; client must reconnect
+			}
+			writeSSEEvent(w, evt.Event, evt.Task)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func eventNameFor(t Task) string {
+	if t.Deleted {
+		return "deleted"
+	}
+	return "updated"
+}
+
+func writeSSEEvent(w http.ResponseWriter, event string, task Task) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", task.Rev, event, data)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInMemorySubscribeReceivesCreateAndUpdateEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTaskRepo(fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	events, _, cancel := repo.Subscribe("alice", 0)
+	defer cancel()
+
+	task, err := repo.Create(ctx, "ship it", "alice")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	select {
+	case evt := <-events:
+		if evt.Event != "created" || evt.Task.ID != task.ID {
+			t.Fatalf("unexpected event: %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for created event")
+	}
+
+	if _, err := repo.SetDone(ctx, task.ID, "alice", true); err != nil {
+		t.Fatalf("SetDone: %v", err)
+	}
+	select {
+	case evt := <-events:
+		if evt.Event != "updated" || !evt.Task.Done {
+			t.Fatalf("unexpected event: %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for updated event")
+	}
+}
+
+func TestInMemorySubscribeIsScopedToOwner(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTaskRepo(fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	events, _, cancel := repo.Subscribe("alice", 0)
+	defer cancel()
+
+	if _, err := repo.Create(ctx, "bob's task", "bob"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	select {
+	case evt := <-events:
+		t.Fatalf("alice's subscription received bob's event: %+v", evt)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestInMemoryNotifyDropsSlowSubscribers(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTaskRepo(fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	events, _, cancel := repo.Subscribe("alice", 0)
+	defer cancel()
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		if _, err := repo.Create(ctx, "task", "alice"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	for range subscriberBuffer {
+		if _, ok := <-events; !ok {
+			t.Fatal("channel closed before buffer was drained")
+		}
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("overflowing subscriber was not dropped")
+	}
+}
+
+// TestHTTPHandlerEventsReplaysBacklogExactlyOnce guards the Subscribe/
+// ChangesSince ordering fix: a task created before the request arrives must
+// appear in the replayed backlog exactly once, never duplicated by also
+// arriving on the live channel.
+func TestHTTPHandlerEventsReplaysBacklogExactlyOnce(t *testing.T) {
+	clock := fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	repo := NewInMemoryTaskRepo(clock)
+	h := &HTTPHandler{repo: repo, notifier: repo}
+
+	ctx := context.Background()
+	task, err := repo.Create(ctx, "ship it", "alice")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	reqCtx, cancel := context.WithCancel(context.WithValue(ctx, userIDContextKey, "alice"))
+	req := httptest.NewRequest(http.MethodGet, "/tasks/events", nil).WithContext(reqCtx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		h.Events(rec, req)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Events did not return after its request context was cancelled")
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: created") || !strings.Contains(body, task.ID) {
+		t.Fatalf("body = %q, want a replayed created event for %s", body, task.ID)
+	}
+	if n := strings.Count(body, "event: created"); n != 1 {
+		t.Fatalf("body contained %d created events, want exactly 1 (no duplicate replay): %q", n, body)
+	}
+}
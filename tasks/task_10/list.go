@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ListOptions filters and paginates TaskRepo.List. A zero value lists every
+// non-deleted task for the owner, newest updated_at first, up to
+// defaultListLimit.
+type ListOptions struct {
+	Done         *bool
+	Query        string // case-insensitive substring match on Title
+	UpdatedSince time.Time
+	Limit        int
+	Cursor       string // opaque, as returned in ListResult.NextCursor
+}
+
+type ListResult struct {
+	Items      []Task `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// listCursor is the decoded form of the opaque cursor string: the
+// (updatedAt, id) of the last item on the previous page, which is enough
+// to resume the (updatedAt DESC, id ASC) order List uses regardless of
+// concurrent inserts.
+type listCursor struct {
+	UpdatedAt time.Time
+	ID        string
+}
+
+func encodeListCursor(c listCursor) string {
+	raw := c.UpdatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeListCursor(s string) (listCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return listCursor{}, fmt.Errorf("malformed cursor")
+	}
+	updatedAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return listCursor{}, fmt.Errorf("malformed cursor")
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, updatedAt)
+	if err != nil {
+		return listCursor{}, fmt.Errorf("malformed cursor")
+	}
+	return listCursor{UpdatedAt: parsed, ID: id}, nil
+}
+
+// clampListLimit applies the bounds every TaskRepo.List implementation
+// shares: limit<=0 falls back to defaultListLimit, and anything above
+// maxListLimit is capped there, so InMemory and SQLTaskRepo always agree
+// on how many items a given ListOptions.Limit actually returns.
+func clampListLimit(limit int) int {
+	if limit <= 0 {
+		return defaultListLimit
+	}
+	if limit > maxListLimit {
+		return maxListLimit
+	}
+	return limit
+}
+
+// parseListOptions builds ListOptions from GET /tasks query params:
+// done, q, updatedSince (RFC3339), limit (default 50, max 500) and cursor.
+func parseListOptions(q url.Values) (ListOptions, error) {
+	opts := ListOptions{
+		Query:  q.Get("q"),
+		Cursor: q.Get("cursor"),
+		Limit:  defaultListLimit,
+	}
+
+	if v := q.Get("done"); v != "" {
+		done, err := strconv.ParseBool(v)
+		if err != nil {
+			return ListOptions{}, fmt.Errorf("invalid done: %w", err)
+		}
+		opts.Done = &done
+	}
+
+	if v := q.Get("updatedSince"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return ListOptions{}, fmt.Errorf("invalid updatedSince: %w", err)
+		}
+		opts.UpdatedSince = t
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return ListOptions{}, fmt.Errorf("invalid limit")
+		}
+		opts.Limit = n
+	}
+	if opts.Limit > maxListLimit {
+		opts.Limit = maxListLimit
+	}
+
+	return opts, nil
+}
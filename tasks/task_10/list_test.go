@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRepoListFiltersAndPaginates(t *testing.T) {
+	for name, newRepo := range repoFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepo()
+			const owner = "alice"
+
+			var ids []string
+			for _, title := range []string{"buy milk", "write report", "buy bread"} {
+				task, err := repo.Create(ctx, title, owner)
+				if err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				ids = append(ids, task.ID)
+			}
+			if _, err := repo.SetDone(ctx, ids[0], owner, true); err != nil {
+				t.Fatalf("SetDone: %v", err)
+			}
+
+			result, err := repo.List(ctx, owner, ListOptions{Query: "buy"})
+			if err != nil {
+				t.Fatalf("List(q=buy): %v", err)
+			}
+			if len(result.Items) != 2 {
+				t.Fatalf("List(q=buy) = %+v, want 2 items", result.Items)
+			}
+
+			done := true
+			result, err = repo.List(ctx, owner, ListOptions{Done: &done})
+			if err != nil {
+				t.Fatalf("List(done=true): %v", err)
+			}
+			if len(result.Items) != 1 || result.Items[0].ID != ids[0] {
+				t.Fatalf("List(done=true) = %+v, want [%s]", result.Items, ids[0])
+			}
+
+			result, err = repo.List(ctx, owner, ListOptions{Limit: 1})
+			if err != nil {
+				t.Fatalf("List(limit=1): %v", err)
+			}
+			if len(result.Items) != 1 || result.NextCursor == "" {
+				t.Fatalf("List(limit=1) = %+v, want 1 item with a next cursor", result)
+			}
+			first := result.Items[0]
+
+			result, err = repo.List(ctx, owner, ListOptions{Limit: 1, Cursor: result.NextCursor})
+			if err != nil {
+				t.Fatalf("List(cursor): %v", err)
+			}
+			if len(result.Items) != 1 || result.Items[0].ID == first.ID {
+				t.Fatalf("List(cursor) returned the same page: %+v", result.Items)
+			}
+
+			result, err = repo.List(ctx, owner, ListOptions{})
+			if err != nil {
+				t.Fatalf("List(): %v", err)
+			}
+			if len(result.Items) != 3 || result.NextCursor != "" {
+				t.Fatalf("List() = %+v, want all 3 items and no next cursor", result)
+			}
+
+			// Every backend clamps Limit to maxListLimit the same way, via
+			// clampListLimit - neither should error or apply the raw,
+			// unclamped value.
+			result, err = repo.List(ctx, owner, ListOptions{Limit: maxListLimit + 100})
+			if err != nil {
+				t.Fatalf("List(limit=maxListLimit+100): %v", err)
+			}
+			if len(result.Items) != 3 {
+				t.Fatalf("List(limit=maxListLimit+100) = %+v, want all 3 items", result.Items)
+			}
+		})
+	}
+}
+
+func TestParseListOptions(t *testing.T) {
+	opts, err := parseListOptions(url.Values{
+		"done":  {"true"},
+		"q":     {"milk"},
+		"limit": {"10"},
+	})
+	if err != nil {
+		t.Fatalf("parseListOptions: %v", err)
+	}
+	if opts.Done == nil || !*opts.Done || opts.Query != "milk" || opts.Limit != 10 {
+		t.Fatalf("unexpected opts: %+v", opts)
+	}
+
+	if _, err := parseListOptions(url.Values{"done": {"not-a-bool"}}); err == nil {
+		t.Fatal("parseListOptions accepted an invalid done value")
+	}
+	if _, err := parseListOptions(url.Values{"updatedSince": {"not-a-time"}}); err == nil {
+		t.Fatal("parseListOptions accepted an invalid updatedSince value")
+	}
+	if _, err := parseListOptions(url.Values{"limit": {"0"}}); err == nil {
+		t.Fatal("parseListOptions accepted a non-positive limit")
+	}
+
+	opts, err = parseListOptions(url.Values{"limit": {strconv.Itoa(maxListLimit + 1)}})
+	if err != nil {
+		t.Fatalf("parseListOptions(limit over max): %v", err)
+	}
+	if opts.Limit != maxListLimit {
+		t.Fatalf("Limit = %d, want clamped to %d", opts.Limit, maxListLimit)
+	}
+}
+
+// TestHTTPHandlerGetTasksAppliesQueryParams drives GET /tasks end to end to
+// confirm the query-string wiring in parseListOptions actually reaches
+// TaskRepo.List.
+func TestHTTPHandlerGetTasksAppliesQueryParams(t *testing.T) {
+	clock := fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	repo := NewInMemoryTaskRepo(clock)
+	h := &HTTPHandler{repo: repo}
+
+	ctx := context.WithValue(context.Background(), userIDContextKey, "alice")
+	if _, err := repo.Create(ctx, "buy milk", "alice"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.Create(ctx, "write report", "alice"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks?q=milk", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	h.GetTasks(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+	var result ListResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Title != "buy milk" {
+		t.Fatalf("Items = %+v, want only the milk task", result.Items)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/tasks?limit=not-a-number", nil).WithContext(ctx)
+	rec = httptest.NewRecorder()
+	h.GetTasks(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid limit: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestClampListLimit(t *testing.T) {
+	cases := []struct {
+		limit int
+		want  int
+	}{
+		{limit: 0, want: defaultListLimit},
+		{limit: -5, want: defaultListLimit},
+		{limit: 10, want: 10},
+		{limit: maxListLimit, want: maxListLimit},
+		{limit: maxListLimit + 1, want: maxListLimit},
+	}
+	for _, c := range cases {
+		if got := clampListLimit(c.limit); got != c.want {
+			t.Errorf("clampListLimit(%d) = %d, want %d", c.limit, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// placeholder returns the n-th (1-indexed) bind parameter marker for the
+// given SQL dialect, since database/sql has no portable placeholder syntax.
+func placeholder(dialect string, n int) string {
+	if dialect == "postgres" {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// Migrate applies every pending migrations/*.up.sql file to db in version
+// order, tracking applied versions in a schema_migrations table so it is
+// safe to call on every startup.
+func Migrate(db *sql.DB, dialect string) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return fmt.Errorf("read migrations: %w", err)
+	}
+
+	type migration struct {
+		version int
+		path    string
+	}
+	var pending []migration
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+		version, err := strconv.Atoi(strings.SplitN(e.Name(), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("migration %s: invalid version prefix: %w", e.Name(), err)
+		}
+		pending = append(pending, migration{version: version, path: "migrations/" + e.Name()})
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].version < pending[j].version })
+
+	for _, m := range pending {
+		var applied bool
+		err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = `+placeholder(dialect, 1)+`)`, m.version).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("check migration %d: %w", m.version, err)
+		}
+		if applied {
+			continue
+		}
+
+		sqlBytes, err := migrationFiles.ReadFile(m.path)
+		if err != nil {
+			return fmt.Errorf("read migration %d: %w", m.version, err)
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("begin migration %d: %w", m.version, err)
+		}
+		if _, err := tx.Exec(string(sqlBytes)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("apply migration %d: %w", m.version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (`+placeholder(dialect, 1)+`)`, m.version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("record migration %d: %w", m.version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migration %d: %w", m.version, err)
+		}
+	}
+	return nil
+}
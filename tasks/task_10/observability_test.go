@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestLoggingMiddlewareRecordsStatusAndRoute(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	handler := loggingMiddleware(logger, "GET /tasks/{id}", next)
+	req := httptest.NewRequest(http.MethodGet, "/tasks/42", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+
+	line := buf.String()
+	for _, want := range []string{"route=\"GET /tasks/{id}\"", "status=418", "request_id="} {
+		if !strings.Contains(line, want) {
+			t.Fatalf("log line %q missing %q", line, want)
+		}
+	}
+}
+
+func TestStatusRecorderDefaultsToOKWithoutExplicitWriteHeader(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sr := &statusRecorder{ResponseWriter: rec, status: http.StatusOK}
+	sr.Write([]byte("hi"))
+
+	if sr.status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", sr.status, http.StatusOK)
+	}
+}
+
+func TestMetricsMiddlewareRecordsRequestsAndDuration(t *testing.T) {
+	const route = "GET /tasks/test-metrics-middleware"
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	handler := metricsMiddleware(route, next)
+	req := httptest.NewRequest(http.MethodGet, "/tasks/test-metrics-middleware", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	got := testutil.ToFloat64(httpRequestsTotal.WithLabelValues(route, http.MethodGet, "201"))
+	if got != 1 {
+		t.Fatalf("http_requests_total{route=%q} = %v, want 1", route, got)
+	}
+}
+
+func TestTracingMiddlewarePropagatesSpanContext(t *testing.T) {
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	var sawSpan bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Without the middleware SpanFromContext falls back to the trace
+		// package's own no-op span; only tracer.Start puts a noop.Span here.
+		_, sawSpan = trace.SpanFromContext(r.Context()).(noop.Span)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := tracingMiddleware(tracer, "GET /tasks/{id}", next)
+	req := httptest.NewRequest(http.MethodGet, "/tasks/42", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !sawSpan {
+		t.Fatal("next handler's context carried no span")
+	}
+}
+
+// TestNewHTTPHandlerWiresRoutes exercises the mux NewHTTPHandler builds:
+// /auth/* must be reachable without a token, /tasks must require one, and
+// /metrics must be mounted when Options.Metrics is set.
+func TestNewHTTPHandlerWiresRoutes(t *testing.T) {
+	clock := fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	repo := NewInMemoryTaskRepo(clock)
+	handler := NewHTTPHandler(repo, testAuthConfig(), Options{Metrics: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /tasks without a token: status = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("POST /auth/login unauthenticated: status = %d, want 400 (empty userID), not 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics: status = %d, want 200", rec.Code)
+	}
+}
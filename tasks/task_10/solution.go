@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -29,79 +30,248 @@ type Task struct {
 	Title     string
 	Done      bool
 	UpdatedAt time.Time
+	Rev       uint64
+	Deleted   bool
+	Owner     string
 }
 
+// TaskRepo methods all take a context.Context first, threaded down from the
+// originating HTTP request so tracing spans and SQL query cancellation
+// follow the caller, even though InMemory itself has no use for it.
 type TaskRepo interface {
-	Create(title string) (Task, error)
-	Get(id string) (Task, bool)
-	List() []Task
-	SetDone(id string, done bool) (Task, error)
+	Create(ctx context.Context, title, owner string) (Task, error)
+	Get(ctx context.Context, id, owner string) (Task, bool)
+	// List returns a filtered, paginated page of owner's tasks per opts.
+	List(ctx context.Context, owner string, opts ListOptions) (ListResult, error)
+	SetDone(ctx context.Context, id, owner string, done bool) (Task, error)
+
+	// ApplyChanges upserts each item under owner, resolving conflicts
+	// against the currently stored task last-write-wins by UpdatedAt
+	// (Rev as tiebreak), and returns the winning copy of every item in
+	// order - the caller's own change if it won, the stored one
+	// otherwise.
+	ApplyChanges(ctx context.Context, owner string, items []Task) ([]Task, error)
+	// ChangesSince returns every task owned by owner with Rev > since,
+	// plus the repo's current revision for use as the next sync's since.
+	ChangesSince(ctx context.Context, owner string, since uint64) ([]Task, uint64, error)
 }
 
 type InMemory struct {
 	mu     sync.RWMutex
-	data   map[string]Task
+	data   map[string]map[string]Task // owner -> id -> Task
 	clock  Clock
 	currID int
+	rev    uint64
+
+	subMu sync.Mutex
+	subs  map[string]map[chan TaskEvent]struct{} // owner -> subscriber channels
 }
 
 func NewInMemoryTaskRepo(clock Clock) *InMemory {
 	return &InMemory{
-		data:  make(map[string]Task),
+		data:  make(map[string]map[string]Task),
 		clock: clock,
 	}
 }
 
-func (m *InMemory) Create(title string) (Task, error) {
+func (m *InMemory) Create(ctx context.Context, title, owner string) (Task, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.currID++
 	id := strconv.Itoa(m.currID)
+	m.rev++
 	task := Task{
 		ID:        id,
 		Title:     title,
 		Done:      false,
 		UpdatedAt: m.clock.Now(),
+		Rev:       m.rev,
+		Owner:     owner,
+	}
+	if m.data[owner] == nil {
+		m.data[owner] = make(map[string]Task)
 	}
-	m.data[id] = task
+	m.data[owner][id] = task
+	m.notify(owner, TaskEvent{Event: "created", Task: task})
 
 	return task, nil
 }
 
-func (m *InMemory) Get(id string) (Task, bool) {
+func (m *InMemory) Get(ctx context.Context, id, owner string) (Task, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	task, ok := m.data[id]
-	return task, ok
+	task, ok := m.data[owner][id]
+	if !ok || task.Deleted {
+		return Task{}, false
+	}
+	return task, true
 }
 
-func (m *InMemory) List() []Task {
+func (m *InMemory) List(ctx context.Context, owner string, opts ListOptions) (ListResult, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	list := make([]Task, 0, len(m.data))
-	for _, task := range m.data {
-		list = append(list, task)
+
+	var cursor *listCursor
+	if opts.Cursor != "" {
+		c, err := decodeListCursor(opts.Cursor)
+		if err != nil {
+			return ListResult{}, err
+		}
+		cursor = &c
+	}
+
+	query := strings.ToLower(opts.Query)
+	matched := make([]Task, 0, len(m.data[owner]))
+	for _, task := range m.data[owner] {
+		if task.Deleted {
+			continue
+		}
+		if opts.Done != nil && task.Done != *opts.Done {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(task.Title), query) {
+			continue
+		}
+		if !opts.UpdatedSince.IsZero() && task.UpdatedAt.Before(opts.UpdatedSince) {
+			continue
+		}
+		matched = append(matched, task)
+	}
+	slices.SortFunc(matched, compareTasksForList)
+
+	start := len(matched)
+	if cursor == nil {
+		start = 0
+	} else {
+		for i, task := range matched {
+			if taskAfterCursor(task, *cursor) {
+				start = i
+				break
+			}
+		}
 	}
-	return list
+
+	limit := clampListLimit(opts.Limit)
+	end := min(start+limit, len(matched))
+	page := matched[start:end]
+
+	var nextCursor string
+	if end < len(matched) {
+		last := page[len(page)-1]
+		nextCursor = encodeListCursor(listCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+	return ListResult{Items: page, NextCursor: nextCursor}, nil
 }
 
-func (m *InMemory) SetDone(id string, done bool) (Task, error) {
+// compareTasksForList orders tasks newest updated_at first, with id (as a
+// plain string, since sync can introduce non-numeric ids) breaking ties -
+// the total order List's cursor pagination relies on.
+func compareTasksForList(a, b Task) int {
+	if !a.UpdatedAt.Equal(b.UpdatedAt) {
+		if a.UpdatedAt.After(b.UpdatedAt) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.ID, b.ID)
+}
+
+// taskAfterCursor reports whether task comes strictly after cursor in the
+// compareTasksForList order.
+func taskAfterCursor(task Task, cursor listCursor) bool {
+	if !task.UpdatedAt.Equal(cursor.UpdatedAt) {
+		return task.UpdatedAt.Before(cursor.UpdatedAt)
+	}
+	return task.ID > cursor.ID
+}
+
+func (m *InMemory) SetDone(ctx context.Context, id, owner string, done bool) (Task, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	task, ok := m.data[id]
-	if !ok {
-		return task, ErrNotFound
+	task, ok := m.data[owner][id]
+	if !ok || task.Deleted {
+		return Task{}, ErrNotFound
 	}
 	task.Done = done
 	task.UpdatedAt = m.clock.Now()
-	m.data[id] = task
+	m.rev++
+	task.Rev = m.rev
+	m.data[owner][id] = task
+	m.notify(owner, TaskEvent{Event: "updated", Task: task})
 	return task, nil
 }
 
+// ApplyChanges upserts each item under owner against the stored copy,
+// last-write-wins by UpdatedAt with Rev as tiebreak (the client's Rev is
+// its last-known server revision for that task; ties favor whichever side
+// is already newer). Every accepted item is re-stamped with a fresh server
+// Rev so ChangesSince can surface it to other clients; a rejected item is
+// answered with the stored copy, whose Rev is left unchanged.
+func (m *InMemory) ApplyChanges(ctx context.Context, owner string, items []Task) ([]Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data[owner] == nil {
+		m.data[owner] = make(map[string]Task)
+	}
+
+	applied := make([]Task, 0, len(items))
+	for _, incoming := range items {
+		incoming.Owner = owner
+		existing, ok := m.data[owner][incoming.ID]
+		if ok && (incoming.UpdatedAt.Before(existing.UpdatedAt) ||
+			(incoming.UpdatedAt.Equal(existing.UpdatedAt) && incoming.Rev <= existing.Rev)) {
+			applied = append(applied, existing)
+			continue
+		}
+
+		event := "updated"
+		switch {
+		case incoming.Deleted:
+			event = "deleted"
+		case !ok:
+			event = "created"
+		}
+
+		m.rev++
+		incoming.Rev = m.rev
+		m.data[owner][incoming.ID] = incoming
+		m.notify(owner, TaskEvent{Event: event, Task: incoming})
+		applied = append(applied, incoming)
+	}
+	return applied, nil
+}
+
+// ChangesSince returns every task owned by owner and touched after
+// revision since, oldest first, alongside the repo's current revision.
+func (m *InMemory) ChangesSince(ctx context.Context, owner string, since uint64) ([]Task, uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var changes []Task
+	for _, task := range m.data[owner] {
+		if task.Rev > since {
+			changes = append(changes, task)
+		}
+	}
+	slices.SortFunc(changes, func(a, b Task) int {
+		switch {
+		case a.Rev < b.Rev:
+			return -1
+		case a.Rev > b.Rev:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return changes, m.rev, nil
+}
+
 type HTTPHandler struct {
-	repo TaskRepo
+	repo     TaskRepo
+	notifier Notifier // set only when repo implements Notifier; nil otherwise
 }
 
 func (h *HTTPHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
@@ -120,11 +290,13 @@ func (h *HTTPHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Empty title", http.StatusBadRequest)
 		return
 	}
-	task, err := h.repo.Create(title)
+	owner, _ := UserIDFromContext(r.Context())
+	task, err := h.repo.Create(r.Context(), title, owner)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	recordTaskOp("create", 1)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -140,11 +312,13 @@ func (h *HTTPHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, ok := h.repo.Get(id)
+	owner, _ := UserIDFromContext(r.Context())
+	task, ok := h.repo.Get(r.Context(), id, owner)
 	if !ok {
 		http.Error(w, "Task not dound", http.StatusNotFound)
 		return
 	}
+	recordTaskOp("get", 0)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -154,37 +328,26 @@ func (h *HTTPHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *HTTPHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
-	tasks := h.repo.List()
-	slices.SortFunc(tasks, func(a, b Task) int {
-		if a.UpdatedAt.Equal(b.UpdatedAt) {
-			aId, err := strconv.Atoi(a.ID)
-			if err != nil {
-				panic(err)
-			}
-			bId, err := strconv.Atoi(b.ID)
-			if err != nil {
-				panic(err)
-			} 
-
-			if aId < bId {
-				return -1
-			} else if bId < aId {
-				return 1
-			} else {
-				return 0
-			}
-		} else if a.UpdatedAt.After(b.UpdatedAt) {
-			return -1
-		} else {
-			return 1
-		}
-	})
+	owner, _ := UserIDFromContext(r.Context())
+
+	opts, err := parseListOptions(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.repo.List(r.Context(), owner, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	recordTaskOp("list", 0)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
 	enc := json.NewEncoder(w)
-	enc.Encode(tasks)
+	enc.Encode(result)
 }
 
 func (h *HTTPHandler) EditTasks(w http.ResponseWriter, r *http.Request) {
@@ -209,11 +372,13 @@ func (h *HTTPHandler) EditTasks(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
-	task, err := h.repo.SetDone(id, *done)
+	owner, _ := UserIDFromContext(r.Context())
+	task, err := h.repo.SetDone(r.Context(), id, owner, *done)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	recordTaskOp("set_done", 0)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -222,12 +387,32 @@ func (h *HTTPHandler) EditTasks(w http.ResponseWriter, r *http.Request) {
 	enc.Encode(task)
 }
 
-func NewHTTPHandler(repo TaskRepo) http.Handler {
+// NewHTTPHandler wires up the public /auth routes and the protected /tasks
+// and /sync routes, which sit behind auth.RequireAuth so every TaskRepo
+// call is scoped to the caller extracted from the bearer token. Each route
+// is wrapped in whatever observability layers opts opts into - see
+// withObservability.
+func NewHTTPHandler(repo TaskRepo, auth AuthConfig, opts Options) http.Handler {
 	hander := &HTTPHandler{repo: repo}
+	if notifier, ok := repo.(Notifier); ok {
+		hander.notifier = notifier
+	}
+	authHandler := &AuthHandler{auth: auth}
+
+	protected := http.NewServeMux()
+	protected.Handle("POST /tasks", withObservability("POST /tasks", opts, hander.CreateTask))
+	protected.Handle("GET /tasks", withObservability("GET /tasks", opts, hander.GetTasks))
+	protected.Handle("GET /tasks/{id}", withObservability("GET /tasks/{id}", opts, hander.GetTask))
+	protected.Handle("PATCH /tasks/{id}", withObservability("PATCH /tasks/{id}", opts, hander.EditTasks))
+	protected.Handle("POST /sync", withObservability("POST /sync", opts, hander.Sync))
+	protected.Handle("GET /tasks/events", withObservability("GET /tasks/events", opts, hander.Events))
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("POST /tasks", hander.CreateTask)
-	mux.HandleFunc("GET /tasks", hander.GetTasks)
-	mux.HandleFunc("GET /tasks/{id}", hander.GetTask)
-	mux.HandleFunc("PATCH /tasks/{id}", hander.EditTasks)
+	mux.Handle("POST /auth/login", withObservability("POST /auth/login", opts, authHandler.Login))
+	mux.Handle("POST /auth/refresh", withObservability("POST /auth/refresh", opts, authHandler.Refresh))
+	if opts.Metrics {
+		mux.Handle("GET /metrics", metricsHandler())
+	}
+	mux.Handle("/", auth.RequireAuth(protected))
 	return mux
 }
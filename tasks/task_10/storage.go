@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+type StorageKind string
+
+const (
+	StorageMemory   StorageKind = "memory"
+	StorageSQLite   StorageKind = "sqlite"
+	StoragePostgres StorageKind = "postgres"
+)
+
+type StorageConfig struct {
+	Kind StorageKind
+	DSN  string
+}
+
+// LoadStorageConfig reads STORAGE (memory|sqlite|postgres, default memory)
+// and DSN from the environment.
+func LoadStorageConfig() (StorageConfig, error) {
+	kind := StorageKind(os.Getenv("STORAGE"))
+	if kind == "" {
+		kind = StorageMemory
+	}
+	dsn := os.Getenv("DSN")
+
+	switch kind {
+	case StorageMemory:
+	case StorageSQLite, StoragePostgres:
+		if dsn == "" {
+			return StorageConfig{}, fmt.Errorf("DSN is required for STORAGE=%s", kind)
+		}
+	default:
+		return StorageConfig{}, fmt.Errorf("unknown STORAGE %q: want memory, sqlite or postgres", kind)
+	}
+	return StorageConfig{Kind: kind, DSN: dsn}, nil
+}
+
+// NewTaskRepo builds the TaskRepo selected by cfg. For SQL-backed kinds it
+// opens the database, applies pending migrations, and returns a close func
+// the caller should run on shutdown; for StorageMemory close is a no-op.
+func NewTaskRepo(cfg StorageConfig, clock Clock) (TaskRepo, func() error, error) {
+	noopClose := func() error { return nil }
+
+	switch cfg.Kind {
+	case StorageMemory, "":
+		return NewInMemoryTaskRepo(clock), noopClose, nil
+	case StorageSQLite:
+		return newSQLTaskRepo("sqlite", cfg.DSN, clock)
+	case StoragePostgres:
+		return newSQLTaskRepo("postgres", cfg.DSN, clock)
+	default:
+		return nil, nil, fmt.Errorf("unknown STORAGE %q", cfg.Kind)
+	}
+}
+
+func newSQLTaskRepo(dialect, dsn string, clock Clock) (TaskRepo, func() error, error) {
+	db, err := sql.Open(dialect, dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open %s: %w", dialect, err)
+	}
+	if err := Migrate(db, dialect); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("migrate %s: %w", dialect, err)
+	}
+	return &SQLTaskRepo{db: db, dialect: dialect, clock: clock}, db.Close, nil
+}
+
+// SQLTaskRepo is a TaskRepo backed by database/sql, shared between the
+// SQLite and Postgres backends. The two dialects only differ in bind
+// parameter syntax, handled via placeholder.
+type SQLTaskRepo struct {
+	db      *sql.DB
+	dialect string
+	clock   Clock
+}
+
+func (r *SQLTaskRepo) ph(n int) string { return placeholder(r.dialect, n) }
+
+// nextID hands out a monotonically increasing integer id from the task_seq
+// counter row seeded by migration 0001, keeping ids comparable the same way
+// InMemory's in-process counter does.
+func (r *SQLTaskRepo) nextID(ctx context.Context, tx *sql.Tx) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, `UPDATE task_seq SET value = value + 1 WHERE id = 1 RETURNING value`).Scan(&id)
+	return id, err
+}
+
+// nextRev hands out the next revision from the rev_seq counter seeded by
+// migration 0002, mirroring InMemory's in-process rev counter.
+func (r *SQLTaskRepo) nextRev(ctx context.Context, q interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}) (uint64, error) {
+	var rev uint64
+	err := q.QueryRowContext(ctx, `UPDATE rev_seq SET value = value + 1 WHERE id = 1 RETURNING value`).Scan(&rev)
+	return rev, err
+}
+
+func (r *SQLTaskRepo) Create(ctx context.Context, title, owner string) (Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Task{}, err
+	}
+	defer tx.Rollback()
+
+	id, err := r.nextID(ctx, tx)
+	if err != nil {
+		return Task{}, err
+	}
+	rev, err := r.nextRev(ctx, tx)
+	if err != nil {
+		return Task{}, err
+	}
+	task := Task{
+		ID:        strconv.Itoa(id),
+		Title:     title,
+		Done:      false,
+		UpdatedAt: r.clock.Now(),
+		Rev:       rev,
+		Owner:     owner,
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO tasks (id, title, done, updated_at, rev, deleted, owner) VALUES (`+
+		r.ph(1)+`, `+r.ph(2)+`, `+r.ph(3)+`, `+r.ph(4)+`, `+r.ph(5)+`, `+r.ph(6)+`, `+r.ph(7)+`)`,
+		task.ID, task.Title, task.Done, task.UpdatedAt, task.Rev, task.Deleted, task.Owner)
+	if err != nil {
+		return Task{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Task{}, err
+	}
+	return task, nil
+}
+
+func (r *SQLTaskRepo) Get(ctx context.Context, id, owner string) (Task, bool) {
+	row := r.db.QueryRowContext(ctx, `SELECT id, title, done, updated_at, rev, deleted, owner FROM tasks `+
+		`WHERE id = `+r.ph(1)+` AND owner = `+r.ph(2)+` AND deleted = false`, id, owner)
+	var t Task
+	if err := row.Scan(&t.ID, &t.Title, &t.Done, &t.UpdatedAt, &t.Rev, &t.Deleted, &t.Owner); err != nil {
+		return Task{}, false
+	}
+	return t, true
+}
+
+// List pushes opts' filtering, ordering and pagination down into SQL
+// instead of loading every row: it fetches one extra row past the limit to
+// tell whether a next page exists.
+func (r *SQLTaskRepo) List(ctx context.Context, owner string, opts ListOptions) (ListResult, error) {
+	limit := clampListLimit(opts.Limit)
+
+	conds := []string{"owner = " + r.ph(1), "deleted = false"}
+	args := []any{owner}
+
+	if opts.Done != nil {
+		args = append(args, *opts.Done)
+		conds = append(conds, "done = "+r.ph(len(args)))
+	}
+	if opts.Query != "" {
+		args = append(args, "%"+strings.ToLower(opts.Query)+"%")
+		conds = append(conds, "LOWER(title) LIKE "+r.ph(len(args)))
+	}
+	if !opts.UpdatedSince.IsZero() {
+		args = append(args, opts.UpdatedSince)
+		conds = append(conds, "updated_at >= "+r.ph(len(args)))
+	}
+	if opts.Cursor != "" {
+		cursor, err := decodeListCursor(opts.Cursor)
+		if err != nil {
+			return ListResult{}, err
+		}
+		args = append(args, cursor.UpdatedAt, cursor.UpdatedAt, cursor.ID)
+		conds = append(conds, fmt.Sprintf("(updated_at < %s OR (updated_at = %s AND id > %s))",
+			r.ph(len(args)-2), r.ph(len(args)-1), r.ph(len(args))))
+	}
+
+	args = append(args, limit+1)
+	query := `SELECT id, title, done, updated_at, rev, deleted, owner FROM tasks WHERE ` +
+		strings.Join(conds, " AND ") +
+		` ORDER BY updated_at DESC, id ASC LIMIT ` + r.ph(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return ListResult{}, err
+	}
+	defer rows.Close()
+
+	var items []Task
+	for rows.Next() {
+		var t Task
+		if err := rows.Scan(&t.ID, &t.Title, &t.Done, &t.UpdatedAt, &t.Rev, &t.Deleted, &t.Owner); err != nil {
+			return ListResult{}, err
+		}
+		items = append(items, t)
+	}
+	if err := rows.Err(); err != nil {
+		return ListResult{}, err
+	}
+
+	var nextCursor string
+	if len(items) > limit {
+		items = items[:limit]
+		last := items[len(items)-1]
+		nextCursor = encodeListCursor(listCursor{UpdatedAt: last.UpdatedAt, ID: last.ID})
+	}
+	return ListResult{Items: items, NextCursor: nextCursor}, nil
+}
+
+func (r *SQLTaskRepo) SetDone(ctx context.Context, id, owner string, done bool) (Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Task{}, err
+	}
+	defer tx.Rollback()
+
+	rev, err := r.nextRev(ctx, tx)
+	if err != nil {
+		return Task{}, err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE tasks SET done = `+r.ph(1)+`, updated_at = `+r.ph(2)+`, rev = `+r.ph(3)+
+		` WHERE id = `+r.ph(4)+` AND owner = `+r.ph(5)+` AND deleted = false`,
+		done, r.clock.Now(), rev, id, owner)
+	if err != nil {
+		return Task{}, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return Task{}, err
+	}
+	if n == 0 {
+		return Task{}, ErrNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return Task{}, err
+	}
+	task, ok := r.Get(ctx, id, owner)
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	return task, nil
+}
+
+// ApplyChanges upserts each item under owner, resolving conflicts the same
+// way InMemory.ApplyChanges does: last-write-wins by UpdatedAt with the
+// existing row's Rev as tiebreak against the incoming item's claimed Rev.
+func (r *SQLTaskRepo) ApplyChanges(ctx context.Context, owner string, items []Task) ([]Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	applied := make([]Task, 0, len(items))
+	for _, incoming := range items {
+		incoming.Owner = owner
+
+		var existing Task
+		var existingOK bool
+		row := tx.QueryRowContext(ctx, `SELECT id, title, done, updated_at, rev, deleted, owner FROM tasks `+
+			`WHERE id = `+r.ph(1)+` AND owner = `+r.ph(2), incoming.ID, owner)
+		switch err := row.Scan(&existing.ID, &existing.Title, &existing.Done, &existing.UpdatedAt, &existing.Rev, &existing.Deleted, &existing.Owner); err {
+		case nil:
+			existingOK = true
+		case sql.ErrNoRows:
+			existingOK = false
+		default:
+			return nil, err
+		}
+
+		if existingOK && (incoming.UpdatedAt.Before(existing.UpdatedAt) ||
+			(incoming.UpdatedAt.Equal(existing.UpdatedAt) && incoming.Rev <= existing.Rev)) {
+			applied = append(applied, existing)
+			continue
+		}
+
+		rev, err := r.nextRev(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		incoming.Rev = rev
+		_, err = tx.ExecContext(ctx, `INSERT INTO tasks (id, title, done, updated_at, rev, deleted, owner) VALUES (`+
+			r.ph(1)+`, `+r.ph(2)+`, `+r.ph(3)+`, `+r.ph(4)+`, `+r.ph(5)+`, `+r.ph(6)+`, `+r.ph(7)+`) `+
+			`ON CONFLICT (id, owner) DO UPDATE SET title = excluded.title, done = excluded.done, `+
+			`updated_at = excluded.updated_at, rev = excluded.rev, deleted = excluded.deleted`,
+			incoming.ID, incoming.Title, incoming.Done, incoming.UpdatedAt, incoming.Rev, incoming.Deleted, incoming.Owner)
+		if err != nil {
+			return nil, err
+		}
+		applied = append(applied, incoming)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// ChangesSince returns every task owned by owner with Rev > since, oldest
+// first, alongside the repo's current revision.
+func (r *SQLTaskRepo) ChangesSince(ctx context.Context, owner string, since uint64) ([]Task, uint64, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, title, done, updated_at, rev, deleted, owner FROM tasks `+
+		`WHERE owner = `+r.ph(1)+` AND rev > `+r.ph(2)+` ORDER BY rev ASC`, owner, since)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var changes []Task
+	for rows.Next() {
+		var t Task
+		if err := rows.Scan(&t.ID, &t.Title, &t.Done, &t.UpdatedAt, &t.Rev, &t.Deleted, &t.Owner); err != nil {
+			return nil, 0, err
+		}
+		changes = append(changes, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var newSince uint64
+	if err := r.db.QueryRowContext(ctx, `SELECT value FROM rev_seq WHERE id = 1`).Scan(&newSince); err != nil {
+		return nil, 0, err
+	}
+	return changes, newSince, nil
+}
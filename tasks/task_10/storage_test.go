@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+// repoFactories enumerates every TaskRepo backend the contract suite below
+// runs against. SQLite and Postgres are only exercised when their DSN env
+// var is set, so this file doubles as the integration suite for CI jobs
+// that wire up real databases. Each backend is freshly isolated per call -
+// SQLite gets its own temp-dir file and Postgres gets truncated - so state
+// from one Test function's subtest can't leak into another's.
+func repoFactories(t *testing.T) map[string]func() TaskRepo {
+	clock := fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	factories := map[string]func() TaskRepo{
+		"memory": func() TaskRepo { return NewInMemoryTaskRepo(clock) },
+	}
+
+	if _, enabled := os.LookupEnv("TEST_SQLITE_DSN"); enabled {
+		dsn := filepath.Join(t.TempDir(), "test.db")
+		factories["sqlite"] = func() TaskRepo {
+			repo, _, err := NewTaskRepo(StorageConfig{Kind: StorageSQLite, DSN: dsn}, clock)
+			if err != nil {
+				t.Fatalf("sqlite repo: %v", err)
+			}
+			return repo
+		}
+	}
+	if dsn := os.Getenv("TEST_POSTGRES_DSN"); dsn != "" {
+		factories["postgres"] = func() TaskRepo {
+			repo, _, err := NewTaskRepo(StorageConfig{Kind: StoragePostgres, DSN: dsn}, clock)
+			if err != nil {
+				t.Fatalf("postgres repo: %v", err)
+			}
+			sqlRepo, ok := repo.(*SQLTaskRepo)
+			if !ok {
+				t.Fatalf("postgres repo is %T, want *SQLTaskRepo", repo)
+			}
+			resetPostgresState(t, sqlRepo)
+			return repo
+		}
+	}
+	return factories
+}
+
+// resetPostgresState truncates the shared Postgres database's task rows and
+// counters before a test uses them. Unlike SQLite, Postgres tests share one
+// long-lived server, so each factory call must wipe it rather than start
+// from a fresh file.
+func resetPostgresState(t *testing.T, repo *SQLTaskRepo) {
+	t.Helper()
+	for _, stmt := range []string{
+		`DELETE FROM tasks`,
+		`UPDATE task_seq SET value = 0 WHERE id = 1`,
+		`UPDATE rev_seq SET value = 0 WHERE id = 1`,
+	} {
+		if _, err := repo.db.Exec(stmt); err != nil {
+			t.Fatalf("reset postgres state: %v", err)
+		}
+	}
+}
+
+// TestApplyChangesScopedToOwner guards the (id, owner) uniqueness fix:
+// two different owners syncing a client-chosen id that happens to collide
+// must each get their own row, not one clobbering the other's task.
+func TestApplyChangesScopedToOwner(t *testing.T) {
+	for name, newRepo := range repoFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepo()
+
+			const sharedID = "client-chosen-1"
+			now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			if _, err := repo.ApplyChanges(ctx, "alice", []Task{
+				{ID: sharedID, Title: "alice's task", UpdatedAt: now},
+			}); err != nil {
+				t.Fatalf("ApplyChanges(alice): %v", err)
+			}
+			if _, err := repo.ApplyChanges(ctx, "bob", []Task{
+				{ID: sharedID, Title: "bob's task", UpdatedAt: now},
+			}); err != nil {
+				t.Fatalf("ApplyChanges(bob): %v", err)
+			}
+
+			alice, ok := repo.Get(ctx, sharedID, "alice")
+			if !ok || alice.Title != "alice's task" {
+				t.Fatalf("alice's task = %+v, %v; want alice's task intact", alice, ok)
+			}
+			bob, ok := repo.Get(ctx, sharedID, "bob")
+			if !ok || bob.Title != "bob's task" {
+				t.Fatalf("bob's task = %+v, %v; want bob's task intact", bob, ok)
+			}
+		})
+	}
+}
+
+func TestTaskRepoContract(t *testing.T) {
+	for name, newRepo := range repoFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepo()
+			const owner = "alice"
+
+			task, err := repo.Create(ctx, "write tests", owner)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if task.Title != "write tests" || task.Done || task.Owner != owner {
+				t.Fatalf("unexpected task: %+v", task)
+			}
+
+			got, ok := repo.Get(ctx, task.ID, owner)
+			if !ok || got != task {
+				t.Fatalf("Get = %+v, %v; want %+v, true", got, ok, task)
+			}
+
+			if _, ok := repo.Get(ctx, "does-not-exist", owner); ok {
+				t.Fatalf("Get of missing id returned ok")
+			}
+			if _, ok := repo.Get(ctx, task.ID, "bob"); ok {
+				t.Fatalf("Get leaked a task across owners")
+			}
+
+			done, err := repo.SetDone(ctx, task.ID, owner, true)
+			if err != nil {
+				t.Fatalf("SetDone: %v", err)
+			}
+			if !done.Done {
+				t.Fatalf("SetDone did not persist")
+			}
+
+			if _, err := repo.SetDone(ctx, "does-not-exist", owner, true); err != ErrNotFound {
+				t.Fatalf("SetDone of missing id = %v, want ErrNotFound", err)
+			}
+			if _, err := repo.SetDone(ctx, task.ID, "bob", true); err != ErrNotFound {
+				t.Fatalf("SetDone across owners = %v, want ErrNotFound", err)
+			}
+
+			result, err := repo.List(ctx, owner, ListOptions{})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(result.Items) != 1 || result.Items[0].ID != task.ID {
+				t.Fatalf("List = %+v, want single task %+v", result, task)
+			}
+			if result, err := repo.List(ctx, "bob", ListOptions{}); err != nil || len(result.Items) != 0 {
+				t.Fatalf("List leaked a task across owners: %+v, %v", result, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type SyncRequest struct {
+	Since   uint64 `json:"since"`
+	Changes []Task `json:"changes"`
+}
+
+type SyncResponse struct {
+	Changes  []Task `json:"changes"`
+	NewSince uint64 `json:"newSince"`
+}
+
+// Sync handles POST /sync: it applies the client's batch of offline edits
+// (including tombstones for deletes) via TaskRepo.ApplyChanges, then
+// returns every change the repo has recorded since the client's last known
+// revision so the client can reconcile without a full re-download.
+func (h *HTTPHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	var req SyncRequest
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	owner, _ := UserIDFromContext(r.Context())
+
+	if len(req.Changes) > 0 {
+		// Snapshot what owner's tasks looked like before applying, so the
+		// tasks_total delta reflects transitions ApplyChanges actually made
+		// rather than whatever Deleted happens to be on the response - a
+		// stale conflict or a resent tombstone must not move the gauge.
+		before, _, err := h.repo.ChangesSince(r.Context(), owner, 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		existedBefore := make(map[string]bool, len(before))
+		for _, t := range before {
+			existedBefore[t.ID] = !t.Deleted
+		}
+
+		applied, err := h.repo.ApplyChanges(r.Context(), owner, req.Changes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		recordTaskOp("apply_changes", syncDelta(existedBefore, applied))
+	}
+
+	changes, newSince, err := h.repo.ChangesSince(r.Context(), owner, req.Since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	recordTaskOp("changes_since", 0)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	enc.Encode(SyncResponse{Changes: changes, NewSince: newSince})
+}
+
+// syncDelta reports the net change in task count a sync's ApplyChanges
+// result represents: +1 for each item that didn't exist in existedBefore
+// and came back alive, -1 for each that was alive in existedBefore and came
+// back deleted. Anything else - a stale conflict the existing row won, or a
+// tombstone that was already recorded - leaves the count unchanged.
+func syncDelta(existedBefore map[string]bool, applied []Task) float64 {
+	var delta float64
+	for _, t := range applied {
+		wasAlive, known := existedBefore[t.ID]
+		switch {
+		case !known && !t.Deleted:
+			delta++
+		case known && wasAlive && t.Deleted:
+			delta--
+		}
+	}
+	return delta
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRepoApplyChangesAndChangesSince(t *testing.T) {
+	for name, newRepo := range repoFactories(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepo()
+			const owner = "alice"
+
+			task, err := repo.Create(ctx, "offline edit", owner)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			changes, since, err := repo.ChangesSince(ctx, owner, 0)
+			if err != nil {
+				t.Fatalf("ChangesSince: %v", err)
+			}
+			if len(changes) != 1 || changes[0].ID != task.ID {
+				t.Fatalf("ChangesSince(0) = %+v, want [%+v]", changes, task)
+			}
+
+			// A stale change (older UpdatedAt, stale Rev) must lose to the
+			// current stored copy.
+			stale := task
+			stale.Title = "stale title"
+			stale.UpdatedAt = stale.UpdatedAt.Add(-1)
+			applied, err := repo.ApplyChanges(ctx, owner, []Task{stale})
+			if err != nil {
+				t.Fatalf("ApplyChanges(stale): %v", err)
+			}
+			if len(applied) != 1 || applied[0].Title == "stale title" {
+				t.Fatalf("ApplyChanges(stale) = %+v, want the existing task to win", applied)
+			}
+
+			// A newer tombstone must win and show up in the next sync.
+			tombstone := task
+			tombstone.Deleted = true
+			tombstone.UpdatedAt = task.UpdatedAt.Add(time.Second)
+			if _, err := repo.ApplyChanges(ctx, owner, []Task{tombstone}); err != nil {
+				t.Fatalf("ApplyChanges(tombstone): %v", err)
+			}
+
+			if _, ok := repo.Get(ctx, task.ID, owner); ok {
+				t.Fatalf("Get returned a deleted task")
+			}
+
+			changes, newSince, err := repo.ChangesSince(ctx, owner, since)
+			if err != nil {
+				t.Fatalf("ChangesSince: %v", err)
+			}
+			if len(changes) != 1 || !changes[0].Deleted {
+				t.Fatalf("ChangesSince(%d) = %+v, want a single tombstone", since, changes)
+			}
+			if newSince <= since {
+				t.Fatalf("newSince = %d, want > %d", newSince, since)
+			}
+		})
+	}
+}
+
+// TestHTTPHandlerSync drives POST /sync end to end: it applies a batch of
+// offline edits scoped to the caller from the request context, and returns
+// every change since the client's last known revision.
+func TestHTTPHandlerSync(t *testing.T) {
+	clock := fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	repo := NewInMemoryTaskRepo(clock)
+	h := &HTTPHandler{repo: repo}
+
+	body, err := json.Marshal(SyncRequest{
+		Since: 0,
+		Changes: []Task{
+			{ID: "offline-1", Title: "write report", UpdatedAt: clock.now},
+		},
+	})
+	if err != nil {
+		t.Fatalf("marshal request: %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), userIDContextKey, "alice")
+	req := httptest.NewRequest(http.MethodPost, "/sync", bytes.NewReader(body)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	h.Sync(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp SyncResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Changes) != 1 || resp.Changes[0].ID != "offline-1" || resp.Changes[0].Title != "write report" {
+		t.Fatalf("Changes = %+v, want the one applied offline edit", resp.Changes)
+	}
+	if resp.NewSince == 0 {
+		t.Fatalf("NewSince = 0, want a positive revision")
+	}
+
+	if _, ok := repo.Get(context.Background(), "offline-1", "bob"); ok {
+		t.Fatal("Sync applied the change to the wrong owner")
+	}
+}
+
+func TestSyncDelta(t *testing.T) {
+	existedBefore := map[string]bool{
+		"alive":           true,
+		"already-deleted": false,
+		"to-delete":       true,
+	}
+
+	applied := []Task{
+		{ID: "new", Deleted: false},            // didn't exist before, now alive: +1
+		{ID: "already-deleted", Deleted: true}, // resent tombstone, no transition: no change
+		{ID: "alive", Deleted: false},          // stale conflict, existing row won unchanged: no change
+		{ID: "to-delete", Deleted: true},       // was alive, freshly tombstoned: -1
+	}
+
+	got := syncDelta(existedBefore, applied)
+	if want := float64(0); got != want {
+		t.Fatalf("syncDelta = %v, want %v", got, want)
+	}
+}